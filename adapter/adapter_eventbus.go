@@ -0,0 +1,73 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"context"
+)
+
+// EventBus publishes and subscribes to named channels. It exists as a small
+// abstraction on top of Redis pub/sub so that Tiered's invalidation mechanism
+// can be swapped for a fake/in-memory implementation in tests.
+type EventBus interface {
+	// Publish sends <message> to every current subscriber of <channel>.
+	Publish(ctx context.Context, channel string, message string) error
+
+	// Subscribe blocks, invoking <handle> for every message received on
+	// <channel>, until the subscription fails or the context is done.
+	Subscribe(ctx context.Context, channel string, handle func(message string)) error
+}
+
+// RedisEventBus is an EventBus implementation backed by Redis PUBLISH/SUBSCRIBE.
+type RedisEventBus struct {
+	redis *Redis
+}
+
+// NewRedisEventBus creates and returns a new EventBus backed by <redis>.
+func NewRedisEventBus(redis *Redis) EventBus {
+	return &RedisEventBus{redis: redis}
+}
+
+// Publish implements the EventBus interface.
+func (b *RedisEventBus) Publish(ctx context.Context, channel string, message string) error {
+	_, err := b.redis.client.DoVar(ctx, "PUBLISH", channel, message)
+	return err
+}
+
+// Subscribe implements the EventBus interface.
+func (b *RedisEventBus) Subscribe(ctx context.Context, channel string, handle func(message string)) error {
+	conn, err := b.redis.client.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	// ReceiveVar below blocks on the subscriber connection regardless of <ctx>, so
+	// closing <conn> is the only way to guarantee cancelling <ctx> actually unblocks
+	// it. done stops this watcher once Subscribe returns for any other reason.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close(ctx)
+		case <-done:
+		}
+	}()
+	if _, err := conn.Do(ctx, "SUBSCRIBE", channel); err != nil {
+		return err
+	}
+	for {
+		v, err := conn.ReceiveVar(ctx)
+		if err != nil {
+			return err
+		}
+		reply := v.Strings()
+		if len(reply) == 3 && reply[0] == "message" {
+			handle(reply[2])
+		}
+	}
+}
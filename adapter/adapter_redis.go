@@ -8,39 +8,124 @@ package adapter
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gogf/gf/container/gvar"
 	"github.com/gogf/gf/database/gredis"
 	"github.com/gogf/gf/os/gcache"
+	"github.com/gogf/gf/util/gconv"
 )
 
 // Redis is the gcache adapter implements using Redis server.
 type Redis struct {
-	redis *gredis.Redis
+	client  RedisClient
+	options Options
 }
 
-// NewRedis newAdapterMemory creates and returns a new memory cache object.
+// NewRedis creates and returns a new gcache adapter using Redis server.
 func NewRedis(redis *gredis.Redis) gcache.Adapter {
+	return NewRedisWithOptions(redis, Options{})
+}
+
+// NewRedisWithOptions creates and returns a new gcache adapter using Redis server,
+// with extra behaviors controlled by <options>.
+//
+// It is recommended to set a unique <options.Prefix> whenever the target Redis
+// database is shared with other applications or other gcache instances, since it
+// keeps Keys, Values, Data, Size and Clear scoped to this instance only.
+func NewRedisWithOptions(redis *gredis.Redis, options Options) gcache.Adapter {
+	return NewRedisWithClient(newGredisClient(redis), options)
+}
+
+// NewRedisWithClient creates and returns a new gcache adapter using <client> instead
+// of the stock single-node *gredis.Redis, with extra behaviors controlled by
+// <options>. This allows a Redis Cluster or Sentinel-aware client to be plugged in:
+// implement RedisClient (and, for cluster deployments, ClusterClient) around
+// whichever driver talks to that topology.
+func NewRedisWithClient(client RedisClient, options Options) gcache.Adapter {
 	return &Redis{
-		redis: redis,
+		client:  client,
+		options: options,
 	}
 }
 
+// clusterClient returns the adapter's client as a ClusterClient when it is aware of
+// operating against a Redis Cluster / sharded Sentinel setup.
+func (c *Redis) clusterClient() (ClusterClient, bool) {
+	cc, ok := c.client.(ClusterClient)
+	return cc, ok
+}
+
+// k prepends the configured prefix to <key> and returns the real key used against Redis.
+func (c *Redis) k(key interface{}) string {
+	if c.options.Prefix == "" {
+		return gconv.String(key)
+	}
+	return c.options.Prefix + gconv.String(key)
+}
+
+// unprefix strips the configured prefix from <key>, returning it unchanged if it does not match.
+func (c *Redis) unprefix(key string) string {
+	if c.options.Prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, c.options.Prefix)
+}
+
+// scanKeys iterates the keyspace with SCAN, matching only keys belonging to this
+// instance, and returns the real (prefixed) key names found.
+//
+// SCAN is used instead of KEYS so that large databases are not blocked by a single
+// O(N) command. When the adapter's client is cluster-aware, every master node is
+// scanned in turn and the results merged, since SCAN only sees the keyspace of the
+// node it is issued against.
+func (c *Redis) scanKeys(ctx context.Context) ([]string, error) {
+	clients, err := c.nodeClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pattern := c.options.Prefix + "*"
+	keys := make([]string, 0)
+	for _, client := range clients {
+		cursor := "0"
+		for {
+			v, err := client.DoVar(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", scanCount)
+			if err != nil {
+				return nil, err
+			}
+			pair := v.Slice()
+			cursor = gconv.String(pair[0])
+			keys = append(keys, gvar.New(pair[1]).Strings()...)
+			if cursor == "0" {
+				break
+			}
+		}
+	}
+	return keys, nil
+}
+
 // Set sets cache with <key>-<value> pair, which is expired after <duration>.
 // It does not expire if <duration> == 0.
 // It deletes the <key> if <duration> < 0 or given <value> is nil.
 func (c *Redis) Set(ctx context.Context, key interface{}, value interface{}, duration time.Duration) error {
 	var err error
+	key = c.k(key)
 	if value == nil || duration < 0 {
-		_, err = c.redis.Ctx(ctx).DoVar("DEL", key)
-	} else {
-		if duration == 0 {
-			_, err = c.redis.Ctx(ctx).DoVar("SET", key, value)
-		} else {
-			_, err = c.redis.Ctx(ctx).DoVar("SETEX", key, uint64(duration.Seconds()), value)
+		_, err = c.client.DoVar(ctx, "DEL", key)
+		return err
+	}
+	if c.options.Codec != nil {
+		if value, err = c.options.Codec.Marshal(value); err != nil {
+			return err
 		}
 	}
+	if duration == 0 {
+		_, err = c.client.DoVar(ctx, "SET", key, value)
+	} else {
+		_, err = c.client.DoVar(ctx, "SETEX", key, uint64(duration.Seconds()), value)
+	}
 	return err
 }
 
@@ -49,13 +134,18 @@ func (c *Redis) Set(ctx context.Context, key interface{}, value interface{}, dur
 //
 // It deletes the <key> if given <value> is nil.
 // It does nothing if <key> does not exist in the cache.
+//
+// <value> is encoded through Options.Codec before being written, exactly like Set,
+// so a key keeps holding a single consistent wire format across repeated Set/Update
+// calls. See Get for how Options.Codec affects the returned <oldValue>.
 func (c *Redis) Update(ctx context.Context, key interface{}, value interface{}) (oldValue *gvar.Var, exist bool, err error) {
 	var (
 		v           *gvar.Var
 		oldDuration time.Duration
 	)
+	key = c.k(key)
 	// TTL.
-	v, err = c.redis.Ctx(ctx).DoVar("TTL", key)
+	v, err = c.client.DoVar(ctx, "TTL", key)
 	if err != nil {
 		return
 	}
@@ -65,25 +155,30 @@ func (c *Redis) Update(ctx context.Context, key interface{}, value interface{})
 		return
 	}
 	// Check existence.
-	v, err = c.redis.Ctx(ctx).DoVar("GET", key)
+	v, err = c.client.DoVar(ctx, "GET", key)
 	if err != nil {
 		return
 	}
 	oldValue = v
 	// DEL.
 	if value == nil {
-		_, err = c.redis.Ctx(ctx).DoVar("DEL", key)
+		_, err = c.client.DoVar(ctx, "DEL", key)
 		if err != nil {
 			return
 		}
 		return
 	}
 	// Update the value.
+	if c.options.Codec != nil {
+		if value, err = c.options.Codec.Marshal(value); err != nil {
+			return
+		}
+	}
 	if oldDuration == -1 {
-		_, err = c.redis.Ctx(ctx).DoVar("SET", key, value)
+		_, err = c.client.DoVar(ctx, "SET", key, value)
 	} else {
 		oldDuration *= time.Second
-		_, err = c.redis.Ctx(ctx).DoVar("SETEX", key, uint64(oldDuration.Seconds()), value)
+		_, err = c.client.DoVar(ctx, "SETEX", key, uint64(oldDuration.Seconds()), value)
 	}
 	return oldValue, true, err
 }
@@ -96,8 +191,9 @@ func (c *Redis) UpdateExpire(ctx context.Context, key interface{}, duration time
 	var (
 		v *gvar.Var
 	)
+	key = c.k(key)
 	// TTL.
-	v, err = c.redis.Ctx(ctx).DoVar("TTL", key)
+	v, err = c.client.DoVar(ctx, "TTL", key)
 	if err != nil {
 		return
 	}
@@ -110,20 +206,20 @@ func (c *Redis) UpdateExpire(ctx context.Context, key interface{}, duration time
 	oldDuration *= time.Second
 	// DEL.
 	if duration < 0 {
-		_, err = c.redis.Ctx(ctx).Do("DEL", key)
+		_, err = c.client.DoVar(ctx, "DEL", key)
 		return
 	}
 	// Update the expire.
 	if duration > 0 {
-		_, err = c.redis.Ctx(ctx).Do("EXPIRE", key, uint64(duration.Seconds()))
+		_, err = c.client.DoVar(ctx, "EXPIRE", key, uint64(duration.Seconds()))
 	}
 	// No expire.
 	if duration == 0 {
-		v, err = c.redis.Ctx(ctx).DoVar("GET", key)
+		v, err = c.client.DoVar(ctx, "GET", key)
 		if err != nil {
 			return
 		}
-		_, err = c.redis.Ctx(ctx).Do("SET", key, v.Val())
+		_, err = c.client.DoVar(ctx, "SET", key, v.Val())
 	}
 	return
 }
@@ -133,7 +229,7 @@ func (c *Redis) UpdateExpire(ctx context.Context, key interface{}, duration time
 // It returns 0 if the <key> does not expire.
 // It returns -1 if the <key> does not exist in the cache.
 func (c *Redis) GetExpire(ctx context.Context, key interface{}) (time.Duration, error) {
-	v, err := c.redis.Ctx(ctx).DoVar("TTL", key)
+	v, err := c.client.DoVar(ctx, "TTL", c.k(key))
 	if err != nil {
 		return 0, err
 	}
@@ -158,6 +254,7 @@ func (c *Redis) GetExpire(ctx context.Context, key interface{}) (time.Duration,
 // It deletes the <key> if <duration> < 0 or given <value> is nil.
 func (c *Redis) SetIfNotExist(ctx context.Context, key interface{}, value interface{}, duration time.Duration) (bool, error) {
 	var err error
+	key = c.k(key)
 	// Execute the function and retrieve the result.
 	if f, ok := value.(func() (interface{}, error)); ok {
 		value, err = f()
@@ -167,7 +264,7 @@ func (c *Redis) SetIfNotExist(ctx context.Context, key interface{}, value interf
 	}
 	// DEL.
 	if duration < 0 || value == nil {
-		v, err := c.redis.Ctx(ctx).DoVar("DEL", key, value)
+		v, err := c.client.DoVar(ctx, "DEL", key, value)
 		if err != nil {
 			return false, err
 		}
@@ -177,13 +274,18 @@ func (c *Redis) SetIfNotExist(ctx context.Context, key interface{}, value interf
 			return false, err
 		}
 	}
-	v, err := c.redis.Ctx(ctx).DoVar("SETNX", key, value)
+	if c.options.Codec != nil {
+		if value, err = c.options.Codec.Marshal(value); err != nil {
+			return false, err
+		}
+	}
+	v, err := c.client.DoVar(ctx, "SETNX", key, value)
 	if err != nil {
 		return false, err
 	}
 	if v.Int() > 0 && duration > 0 {
 		// Set the expire.
-		_, err := c.redis.Ctx(ctx).Do("EXPIRE", key, uint64(duration.Seconds()))
+		_, err := c.client.DoVar(ctx, "EXPIRE", key, uint64(duration.Seconds()))
 		if err != nil {
 			return false, err
 		}
@@ -225,71 +327,130 @@ func (c *Redis) SetIfNotExistFunc(ctx context.Context, key interface{}, f func()
 // It does not expire if `duration` == 0.
 // It deletes the `key` if `duration` < 0 or given `value` is nil.
 //
-// Note that it differs from function `SetIfNotExistFunc` is that the function `f` is executed within
-// writing mutex lock for concurrent safety purpose.
+// Note that it differs from function `SetIfNotExistFunc` in that `f` runs only once
+// across all processes racing on the same `key`: the first caller acquires a
+// distributed lock and executes `f`, while every other caller polls for `key` to
+// appear, for up to LockOptions.MaxWait, instead of also calling `f`.
 func (c *Redis) SetIfNotExistFuncLock(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (ok bool, err error) {
-	return c.SetIfNotExistFunc(ctx, key, f, duration)
+	v, err := c.Get(ctx, key)
+	if err != nil || v != nil {
+		return false, err
+	}
+	lockOptions := c.options.LockOptions.withDefaults()
+	lockKey := c.lockKey(key)
+	token, acquired, err := c.acquireLock(ctx, lockKey, lockOptions.LockTTL)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		// Someone else is already loading `key`; wait for them instead of also calling `f`.
+		return false, c.waitForKey(ctx, key, lockOptions)
+	}
+	defer c.releaseLock(ctx, lockKey, token)
+	// The previous lock holder may have populated `key` and released the lock
+	// between our first Get above and acquiring it; re-check so `f` still runs
+	// only once per `key`.
+	v, err = c.Get(ctx, key)
+	if err != nil || v != nil {
+		return false, err
+	}
+	value, err := f()
+	if err != nil {
+		return false, err
+	}
+	if value == nil {
+		return false, nil
+	}
+	return true, c.Set(ctx, key, value, duration)
 }
 
 // Sets batch sets cache with key-value pairs by <data>, which is expired after <duration>.
 //
 // It does not expire if <duration> == 0.
 // It deletes the keys of <data> if <duration> < 0 or given <value> is nil.
+//
+// Multi-key commands are grouped by hash slot when the adapter's client is
+// cluster-aware, since a single MSET/transaction cannot span more than one slot.
 func (c *Redis) Sets(ctx context.Context, data map[interface{}]interface{}, duration time.Duration) error {
 	if len(data) == 0 {
 		return nil
 	}
 	// DEL.
 	if duration < 0 {
-		var (
-			index = 0
-			keys  = make([]interface{}, len(data))
-		)
-		for k, _ := range data {
-			keys[index] = k
-			index += 1
-		}
-		_, err := c.redis.Ctx(ctx).Do("DEL", keys...)
-		if err != nil {
-			return err
-		}
-	}
-	if duration == 0 {
-		var (
-			index     = 0
-			keyValues = make([]interface{}, len(data)*2)
-		)
-		for k, v := range data {
-			keyValues[index] = k
-			keyValues[index+1] = v
-			index += 2
-		}
-		_, err := c.redis.Ctx(ctx).Do("MSET", keyValues...)
-		if err != nil {
-			return err
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, c.k(k))
 		}
+		return c.delBySlot(ctx, keys)
 	}
-	if duration > 0 {
-		var err error
-		for k, v := range data {
-			if err = c.Set(ctx, k, v, duration); err != nil {
+	pairs := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if c.options.Codec != nil {
+			encoded, err := c.options.Codec.Marshal(v)
+			if err != nil {
 				return err
 			}
+			v = encoded
 		}
+		pairs[c.k(k)] = v
 	}
-	return nil
+	if duration == 0 {
+		return c.msetBySlot(ctx, pairs)
+	}
+	// Wrap the SETEX commands in per-slot MULTI/EXEC transactions so the group
+	// applies atomically; see setexBySlot for why this does not reduce round trips.
+	return c.setexBySlot(ctx, pairs, duration)
 }
 
 // Get retrieves and returns the associated value of given <key>.
 // It returns nil if it does not exist or its value is nil.
+//
+// If Options.Codec is configured, the returned value is the raw codec-encoded
+// bytes written by Set, since Get has no destination type to decode into; use
+// GetInto to decode a key's value into a caller-supplied pointer instead.
 func (c *Redis) Get(ctx context.Context, key interface{}) (*gvar.Var, error) {
-	v, err := c.redis.Ctx(ctx).DoVar("GET", key)
+	v, err := c.client.DoVar(ctx, "GET", c.k(key))
 	if err != nil {
 		return nil, err
 	}
 	return v, nil
 }
 
+// GetInto retrieves the value of <key> and decodes it into <dst> using the configured
+// Options.Codec. <dst> must be a non-nil pointer. It does nothing and returns nil if
+// <key> does not exist in the cache.
+//
+// It returns an error if no Options.Codec was configured for this adapter, since the
+// raw bytes stored in Redis would otherwise be of unknown shape.
+func (c *Redis) GetInto(ctx context.Context, key interface{}, dst interface{}) error {
+	if c.options.Codec == nil {
+		return errors.New("adapter: GetInto requires Options.Codec to be set")
+	}
+	v, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return c.options.Codec.Unmarshal(v.Bytes(), dst)
+}
+
+// encodedVar wraps <value> the same way Get would return it: as the raw
+// codec-encoded bytes when Options.Codec is configured, or as <value> itself
+// otherwise. It keeps the cache-miss return value of GetOrSet* consistent with the
+// cache-hit one, which always comes straight from Get.
+func (c *Redis) encodedVar(value interface{}) (*gvar.Var, error) {
+	if c.options.Codec == nil {
+		return gvar.New(value), nil
+	}
+	encoded, err := c.options.Codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return gvar.New(encoded), nil
+}
+
 // GetOrSet retrieves and returns the value of <key>, or sets <key>-<value> pair and
 // returns <value> if <key> does not exist in the cache. The key-value pair expires
 // after <duration>.
@@ -297,13 +458,19 @@ func (c *Redis) Get(ctx context.Context, key interface{}) (*gvar.Var, error) {
 // It does not expire if <duration> == 0.
 // It deletes the <key> if <duration> < 0 or given <value> is nil, but it does nothing
 // if <value> is a function and the function result is nil.
+//
+// See Get for how Options.Codec affects the returned value.
 func (c *Redis) GetOrSet(ctx context.Context, key interface{}, value interface{}, duration time.Duration) (*gvar.Var, error) {
 	v, err := c.Get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 	if v == nil {
-		return gvar.New(value), c.Set(ctx, key, value, duration)
+		result, err := c.encodedVar(value)
+		if err != nil {
+			return nil, err
+		}
+		return result, c.Set(ctx, key, value, duration)
 	} else {
 		return v, nil
 	}
@@ -316,6 +483,8 @@ func (c *Redis) GetOrSet(ctx context.Context, key interface{}, value interface{}
 // It does not expire if <duration> == 0.
 // It deletes the <key> if <duration> < 0 or given <value> is nil, but it does nothing
 // if <value> is a function and the function result is nil.
+//
+// See Get for how Options.Codec affects the returned value.
 func (c *Redis) GetOrSetFunc(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (*gvar.Var, error) {
 	v, err := c.Get(ctx, key)
 	if err != nil {
@@ -329,7 +498,11 @@ func (c *Redis) GetOrSetFunc(ctx context.Context, key interface{}, f func() (int
 		if value == nil {
 			return nil, nil
 		}
-		return gvar.New(value), c.Set(ctx, key, value, duration)
+		result, err := c.encodedVar(value)
+		if err != nil {
+			return nil, err
+		}
+		return result, c.Set(ctx, key, value, duration)
 	} else {
 		return v, nil
 	}
@@ -342,15 +515,55 @@ func (c *Redis) GetOrSetFunc(ctx context.Context, key interface{}, f func() (int
 // It does not expire if <duration> == 0.
 // It does nothing if function <f> returns nil.
 //
-// Note that the function <f> should be executed within writing mutex lock for concurrent
-// safety purpose.
+// Note that it differs from function <GetOrSetFunc> in that <f> runs only once
+// across all processes racing on the same <key>: the first caller acquires a
+// distributed lock and executes <f>, while every other caller polls for <key> to
+// appear, for up to LockOptions.MaxWait, and then re-reads it instead of also calling <f>.
+//
+// See Get for how Options.Codec affects the returned value.
 func (c *Redis) GetOrSetFuncLock(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (*gvar.Var, error) {
-	return c.GetOrSetFunc(ctx, key, f, duration)
+	v, err := c.Get(ctx, key)
+	if err != nil || v != nil {
+		return v, err
+	}
+	lockOptions := c.options.LockOptions.withDefaults()
+	lockKey := c.lockKey(key)
+	token, acquired, err := c.acquireLock(ctx, lockKey, lockOptions.LockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		// Someone else is already loading `key`; wait for them instead of also calling `f`.
+		if err := c.waitForKey(ctx, key, lockOptions); err != nil {
+			return nil, err
+		}
+		return c.Get(ctx, key)
+	}
+	defer c.releaseLock(ctx, lockKey, token)
+	// The previous lock holder may have populated `key` and released the lock
+	// between our first Get above and acquiring it; re-check so `f` still runs
+	// only once per `key`.
+	v, err = c.Get(ctx, key)
+	if err != nil || v != nil {
+		return v, err
+	}
+	value, err := f()
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	result, err := c.encodedVar(value)
+	if err != nil {
+		return nil, err
+	}
+	return result, c.Set(ctx, key, value, duration)
 }
 
 // Contains returns true if <key> exists in the cache, or else returns false.
 func (c *Redis) Contains(ctx context.Context, key interface{}) (bool, error) {
-	v, err := c.redis.Ctx(ctx).DoVar("EXISTS", key)
+	v, err := c.client.DoVar(ctx, "EXISTS", c.k(key))
 	if err != nil {
 		return false, err
 	}
@@ -359,90 +572,193 @@ func (c *Redis) Contains(ctx context.Context, key interface{}) (bool, error) {
 
 // Remove deletes the one or more keys from cache, and returns its value.
 // If multiple keys are given, it returns the value of the deleted last item.
+//
+// The DEL is grouped by hash slot when the adapter's client is cluster-aware, since
+// a single DEL cannot span more than one slot.
 func (c *Redis) Remove(ctx context.Context, keys ...interface{}) (value *gvar.Var, err error) {
 	if len(keys) == 0 {
 		return nil, nil
 	}
+	realKeys := make([]string, len(keys))
+	for i, key := range keys {
+		realKeys[i] = c.k(key)
+	}
 	// Retrieves the last key value.
-	if v, err := c.redis.Ctx(ctx).DoVar("GET", keys[len(keys)-1]); err != nil {
+	if v, err := c.client.DoVar(ctx, "GET", realKeys[len(realKeys)-1]); err != nil {
 		return nil, err
 	} else {
 		value = v
 	}
 	// Deletes all given keys.
-	_, err = c.redis.Ctx(ctx).DoVar("DEL", keys...)
+	err = c.delBySlot(ctx, realKeys)
 	return value, err
 }
 
 // Data returns a copy of all key-value pairs in the cache as map type.
+//
+// Keys are discovered with SCAN rather than KEYS, and only keys belonging to this
+// instance's prefix are returned. The MGET is grouped by hash slot when the
+// adapter's client is cluster-aware.
+//
+// See Get for how Options.Codec affects the values returned.
 func (c *Redis) Data(ctx context.Context) (map[interface{}]interface{}, error) {
-	// Keys.
-	v, err := c.redis.Ctx(ctx).DoVar("KEYS", "*")
-	if err != nil {
-		return nil, err
-	}
-	keys := v.Slice()
-	// Values.
-	v, err = c.redis.Ctx(ctx).DoVar("MGET", keys...)
+	keys, err := c.scanKeys(ctx)
 	if err != nil {
 		return nil, err
 	}
-	values := v.Slice()
-	// Compose keys and values.
 	data := make(map[interface{}]interface{})
-	for i := 0; i < len(keys); i++ {
-		data[keys[i]] = values[i]
+	if len(keys) == 0 {
+		return data, nil
 	}
-	return data, nil
+	err = c.forEachSlotGroup(keys, func(group []string) error {
+		args := make([]interface{}, len(group))
+		for i, key := range group {
+			args[i] = key
+		}
+		v, err := c.client.DoVar(ctx, "MGET", args...)
+		if err != nil {
+			return err
+		}
+		values := v.Slice()
+		for i, key := range group {
+			data[c.unprefix(key)] = values[i]
+		}
+		return nil
+	})
+	return data, err
 }
 
 // Keys returns all keys in the cache as slice.
+//
+// It uses SCAN instead of KEYS so that it does not block the Redis server on large
+// databases, and it only returns keys belonging to this instance's prefix.
 func (c *Redis) Keys(ctx context.Context) ([]interface{}, error) {
-	v, err := c.redis.Ctx(ctx).DoVar("KEYS", "*")
+	keys, err := c.scanKeys(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return v.Slice(), nil
+	result := make([]interface{}, len(keys))
+	for i, key := range keys {
+		result[i] = c.unprefix(key)
+	}
+	return result, nil
 }
 
 // Values returns all values in the cache as slice.
+//
+// It uses SCAN instead of KEYS so that it does not block the Redis server on large
+// databases, and it only returns values belonging to this instance's prefix. The
+// MGET is grouped by hash slot when the adapter's client is cluster-aware.
+//
+// See Get for how Options.Codec affects the values returned.
 func (c *Redis) Values(ctx context.Context) ([]interface{}, error) {
-	// Keys.
-	v, err := c.redis.Ctx(ctx).DoVar("KEYS", "*")
+	keys, err := c.scanKeys(ctx)
 	if err != nil {
 		return nil, err
 	}
-	keys := v.Slice()
-	// Values.
-	v, err = c.redis.Ctx(ctx).DoVar("MGET", keys...)
-	if err != nil {
-		return nil, err
+	if len(keys) == 0 {
+		return []interface{}{}, nil
 	}
-	return v.Slice(), nil
+	result := make([]interface{}, 0, len(keys))
+	err = c.forEachSlotGroup(keys, func(group []string) error {
+		args := make([]interface{}, len(group))
+		for i, key := range group {
+			args[i] = key
+		}
+		v, err := c.client.DoVar(ctx, "MGET", args...)
+		if err != nil {
+			return err
+		}
+		result = append(result, v.Slice()...)
+		return nil
+	})
+	return result, err
 }
 
 // Size returns the size of the cache.
+//
+// If a prefix is configured, DBSIZE cannot be used since it reports the size of the
+// whole Redis database, so the keys belonging to this instance are counted via SCAN
+// instead. In cluster mode without a prefix, DBSIZE is rerouted to a fan-out sum
+// across every master node, since issuing it against a single node would only
+// report that node's share of the keyspace.
 func (c *Redis) Size(ctx context.Context) (size int, err error) {
-	v, err := c.redis.Ctx(ctx).DoVar("DBSIZE")
+	if c.options.Prefix == "" {
+		clients, err := c.nodeClients(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total := 0
+		for _, client := range clients {
+			v, err := client.DoVar(ctx, "DBSIZE")
+			if err != nil {
+				return 0, err
+			}
+			total += v.Int()
+		}
+		return total, nil
+	}
+	keys, err := c.scanKeys(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return v.Int(), nil
+	return len(keys), nil
 }
 
 // Clear clears all data of the cache.
+//
+// If a prefix is configured, FLUSHDB cannot be used since it would also remove keys
+// belonging to other applications or instances sharing the same Redis database, so
+// matching keys are discovered via SCAN and removed in UNLINK batches instead,
+// grouped by hash slot when the adapter's client is cluster-aware. Without a prefix
+// in cluster mode, FLUSHDB is rerouted to fan out to every master node, since
+// issuing it against a single node would only clear that node's shard.
 // Note that this function is sensitive and should be carefully used.
 func (c *Redis) Clear(ctx context.Context) error {
-	// The "FLUSHDB" may not be available.
-	if _, err := c.redis.Ctx(ctx).DoVar("FLUSHDB"); err != nil {
-		keys, err := c.Keys(ctx)
-		if err != nil {
+	if c.options.Prefix == "" {
+		if clients, ok := c.clusterClient(); ok {
+			nodes, err := clients.Nodes(ctx)
+			if err != nil {
+				return err
+			}
+			for _, node := range nodes {
+				if _, err := node.DoVar(ctx, "FLUSHDB"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		// The "FLUSHDB" may not be available.
+		if _, err := c.client.DoVar(ctx, "FLUSHDB"); err != nil {
+			keys, err := c.Keys(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = c.Remove(ctx, keys...)
 			return err
 		}
-		_, err = c.Remove(ctx, keys...)
+		return nil
+	}
+	keys, err := c.scanKeys(ctx)
+	if err != nil {
 		return err
 	}
-	return nil
+	return c.forEachSlotGroup(keys, func(group []string) error {
+		for start := 0; start < len(group); start += scanCount {
+			end := start + scanCount
+			if end > len(group) {
+				end = len(group)
+			}
+			batch := make([]interface{}, end-start)
+			for i, key := range group[start:end] {
+				batch[i] = key
+			}
+			if _, err := c.client.DoVar(ctx, "UNLINK", batch...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // Close closes the cache.
@@ -0,0 +1,66 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"context"
+
+	"github.com/gogf/gf/container/gvar"
+)
+
+// GetMulti retrieves and returns the values of the given <keys> via MGET, instead of
+// calling Get once per key. The MGET is grouped by hash slot when the adapter's
+// client is cluster-aware, since a single MGET cannot span more than one slot.
+//
+// See Get for how Options.Codec affects the values returned.
+func (c *Redis) GetMulti(ctx context.Context, keys ...interface{}) (map[interface{}]*gvar.Var, error) {
+	result := make(map[interface{}]*gvar.Var, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+	realKeys := make([]string, len(keys))
+	for i, key := range keys {
+		realKeys[i] = c.k(key)
+	}
+	for _, idxs := range c.slotIndexGroups(realKeys) {
+		args := make([]interface{}, len(idxs))
+		for i, idx := range idxs {
+			args[i] = realKeys[idx]
+		}
+		v, err := c.client.DoVar(ctx, "MGET", args...)
+		if err != nil {
+			return nil, err
+		}
+		values := v.Slice()
+		for i, idx := range idxs {
+			result[keys[idx]] = gvar.New(values[i])
+		}
+	}
+	return result, nil
+}
+
+// RemoveMulti deletes the given <keys> from cache without reading back the value of
+// the last one first, using UNLINK so that the memory reclaim happens asynchronously
+// and the caller is not blocked by it. The UNLINK is grouped by hash slot when the
+// adapter's client is cluster-aware.
+func (c *Redis) RemoveMulti(ctx context.Context, keys ...interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	realKeys := make([]string, len(keys))
+	for i, key := range keys {
+		realKeys[i] = c.k(key)
+	}
+	return c.forEachSlotGroup(realKeys, func(group []string) error {
+		args := make([]interface{}, len(group))
+		for i, key := range group {
+			args[i] = key
+		}
+		_, err := c.client.DoVar(ctx, "UNLINK", args...)
+		return err
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"context"
+
+	"github.com/gogf/gf/container/gvar"
+	"github.com/gogf/gf/database/gredis"
+)
+
+// RedisClient is the subset of behavior the adapter depends on from a Redis
+// connection. The stock single-node *gredis.Redis satisfies it out of the box via
+// NewRedis/NewRedisWithOptions; NewRedisWithClient accepts any other implementation,
+// which is how a Redis Cluster or Sentinel-aware client is plugged in.
+type RedisClient interface {
+	// DoVar executes a Redis command and returns its result.
+	DoVar(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error)
+
+	// Conn returns a dedicated connection for commands that must share server-side
+	// state across several round trips, such as MULTI/EXEC and SUBSCRIBE.
+	Conn(ctx context.Context) (RedisConn, error)
+}
+
+// RedisConn is a dedicated connection obtained from RedisClient.Conn.
+type RedisConn interface {
+	// Do executes a Redis command on this connection and returns its result.
+	Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error)
+	// ReceiveVar reads the next pub/sub message pushed on this connection.
+	ReceiveVar(ctx context.Context) (*gvar.Var, error)
+	// Close releases the connection back to the client.
+	Close(ctx context.Context) error
+}
+
+// gredisClient adapts a stock single-node *gredis.Redis to the RedisClient interface.
+type gredisClient struct {
+	redis *gredis.Redis
+}
+
+// newGredisClient wraps <redis> as a RedisClient.
+func newGredisClient(redis *gredis.Redis) RedisClient {
+	return &gredisClient{redis: redis}
+}
+
+// DoVar implements the RedisClient interface.
+func (g *gredisClient) DoVar(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	return g.redis.Ctx(ctx).DoVar(command, args...)
+}
+
+// Conn implements the RedisClient interface.
+func (g *gredisClient) Conn(ctx context.Context) (RedisConn, error) {
+	return g.redis.Conn(ctx)
+}
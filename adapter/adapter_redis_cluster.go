@@ -0,0 +1,185 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ClusterClient is implemented by RedisClient implementations that are aware of
+// operating against a Redis Cluster (or a sharded Sentinel setup), so the adapter
+// can keep multi-key commands CROSSSLOT-safe and fan out whole-keyspace commands,
+// such as SCAN, DBSIZE and FLUSHDB, to every master node.
+type ClusterClient interface {
+	RedisClient
+	// Nodes returns one RedisClient scoped to each master node of the cluster.
+	Nodes(ctx context.Context) ([]RedisClient, error)
+}
+
+// crc16Table is the CRC16/XMODEM table Redis Cluster uses to compute hash slots.
+var crc16Table = buildCRC16Table()
+
+func buildCRC16Table() [256]uint16 {
+	const poly = 0x1021
+	var table [256]uint16
+	for i := 0; i < 256; i++ {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ poly
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}
+
+// clusterSlotCount is the number of hash slots a Redis Cluster is partitioned into.
+const clusterSlotCount = 16384
+
+// hashSlot returns the Redis Cluster hash slot for <key>, honouring a "{hashtag}"
+// substring so that related keys can be pinned to the same slot, exactly as Redis
+// Cluster itself does.
+func hashSlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^key[i]]
+	}
+	return crc % clusterSlotCount
+}
+
+// groupBySlot groups the indices of <keys> by their Redis Cluster hash slot.
+func groupBySlot(keys []string) map[uint16][]int {
+	groups := make(map[uint16][]int)
+	for i, key := range keys {
+		slot := hashSlot(key)
+		groups[slot] = append(groups[slot], i)
+	}
+	return groups
+}
+
+// slotIndexGroups groups the indices of <keys> by hash slot when the adapter's
+// client is cluster-aware, or returns every index as a single group otherwise. It is
+// used instead of forEachSlotGroup when the caller needs to map each result back to
+// its original, untransformed key.
+func (c *Redis) slotIndexGroups(keys []string) [][]int {
+	if _, ok := c.clusterClient(); !ok {
+		idxs := make([]int, len(keys))
+		for i := range keys {
+			idxs[i] = i
+		}
+		return [][]int{idxs}
+	}
+	groups := groupBySlot(keys)
+	result := make([][]int, 0, len(groups))
+	for _, idxs := range groups {
+		result = append(result, idxs)
+	}
+	return result
+}
+
+// forEachSlotGroup calls <fn> once per hash-slot group of <keys>, so that a single
+// command or transaction never spans more than one Redis Cluster slot. When the
+// adapter's client is not cluster-aware, all of <keys> are treated as a single group,
+// preserving single-node behaviour.
+func (c *Redis) forEachSlotGroup(keys []string, fn func(group []string) error) error {
+	if _, ok := c.clusterClient(); !ok {
+		return fn(keys)
+	}
+	for _, idxs := range groupBySlot(keys) {
+		group := make([]string, len(idxs))
+		for i, idx := range idxs {
+			group[i] = keys[idx]
+		}
+		if err := fn(group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeClients returns one RedisClient per master node when the adapter's client is
+// cluster-aware, or a single-element slice wrapping the adapter's own client
+// otherwise.
+func (c *Redis) nodeClients(ctx context.Context) ([]RedisClient, error) {
+	if cc, ok := c.clusterClient(); ok {
+		return cc.Nodes(ctx)
+	}
+	return []RedisClient{c.client}, nil
+}
+
+// delBySlot issues DEL for <keys>, grouping them by hash slot when the adapter's
+// client is cluster-aware so that a single command never spans more than one slot.
+func (c *Redis) delBySlot(ctx context.Context, keys []string) error {
+	return c.forEachSlotGroup(keys, func(group []string) error {
+		args := make([]interface{}, len(group))
+		for i, key := range group {
+			args[i] = key
+		}
+		_, err := c.client.DoVar(ctx, "DEL", args...)
+		return err
+	})
+}
+
+// msetBySlot issues MSET for <pairs>, grouping them by hash slot when the adapter's
+// client is cluster-aware.
+func (c *Redis) msetBySlot(ctx context.Context, pairs map[string]interface{}) error {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	return c.forEachSlotGroup(keys, func(group []string) error {
+		args := make([]interface{}, 0, len(group)*2)
+		for _, key := range group {
+			args = append(args, key, pairs[key])
+		}
+		_, err := c.client.DoVar(ctx, "MSET", args...)
+		return err
+	})
+}
+
+// setexBySlot issues a SETEX per key in <pairs> inside a MULTI/EXEC transaction,
+// grouping keys by hash slot when the adapter's client is cluster-aware, since a
+// Redis Cluster transaction cannot span more than one slot either.
+//
+// RedisConn.Do is a synchronous request/response round trip, so this is not
+// pipelining: each queued SETEX still waits for its own "+QUEUED" reply, costing
+// len(group)+2 round trips rather than fewer. What MULTI/EXEC buys here is
+// atomicity — every SETEX in the group either all apply or none do — not fewer
+// round trips than issuing each SETEX individually.
+func (c *Redis) setexBySlot(ctx context.Context, pairs map[string]interface{}, duration time.Duration) error {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	return c.forEachSlotGroup(keys, func(group []string) error {
+		conn, err := c.client.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close(ctx)
+		if _, err := conn.Do(ctx, "MULTI"); err != nil {
+			return err
+		}
+		for _, key := range group {
+			if _, err := conn.Do(ctx, "SETEX", key, uint64(duration.Seconds()), pairs[key]); err != nil {
+				return err
+			}
+		}
+		_, err = conn.Do(ctx, "EXEC")
+		return err
+	})
+}
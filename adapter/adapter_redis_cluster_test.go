@@ -0,0 +1,53 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import "testing"
+
+func TestHashSlot_Range(t *testing.T) {
+	for _, key := range []string{"foo", "bar", "{user1000}.following", "gcache-adapter-test:key"} {
+		if slot := hashSlot(key); slot >= clusterSlotCount {
+			t.Fatalf("hashSlot(%q) = %d, want < %d", key, slot, clusterSlotCount)
+		}
+	}
+}
+
+func TestHashSlot_HashTag(t *testing.T) {
+	a := hashSlot("{user1000}.following")
+	b := hashSlot("{user1000}.followers")
+	if a != b {
+		t.Fatalf("keys sharing hashtag {user1000} landed on different slots: %d != %d", a, b)
+	}
+	c := hashSlot("{user2000}.following")
+	if a == c && hashSlot("user1000") != hashSlot("user2000") {
+		// Not a hard requirement, but different hashtags colliding on every call would
+		// indicate the hashtag extraction is broken rather than a coincidence.
+		t.Logf("warning: {user1000} and {user2000} landed on the same slot, which is possible but unlikely")
+	}
+}
+
+func TestGroupBySlot(t *testing.T) {
+	keys := []string{"{a}1", "{a}2", "{b}1", "{b}2", "{b}3"}
+	groups := groupBySlot(keys)
+
+	total := 0
+	for _, idxs := range groups {
+		total += len(idxs)
+	}
+	if total != len(keys) {
+		t.Fatalf("groupBySlot dropped keys: got %d indices total, want %d", total, len(keys))
+	}
+
+	slotA := hashSlot("{a}1")
+	slotB := hashSlot("{b}1")
+	if len(groups[slotA]) != 2 {
+		t.Fatalf("expected 2 keys in slot %d for hashtag {a}, got %d", slotA, len(groups[slotA]))
+	}
+	if len(groups[slotB]) != 3 {
+		t.Fatalf("expected 3 keys in slot %d for hashtag {b}, got %d", slotB, len(groups[slotB]))
+	}
+}
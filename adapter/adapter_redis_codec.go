@@ -0,0 +1,70 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec marshals values into bytes for storage in Redis and unmarshals them back
+// into a caller-supplied pointer, so that Set/GetInto can round-trip arbitrary Go
+// structs, slices and maps without losing type information. See Options.Codec and
+// GetInto for how this fits into the Redis adapter.
+type Codec interface {
+	// Marshal encodes <value> into bytes suitable for storage in Redis.
+	Marshal(value interface{}) ([]byte, error)
+	// Unmarshal decodes bytes previously produced by Marshal into <value>,
+	// which must be a non-nil pointer.
+	Unmarshal(data []byte, value interface{}) error
+}
+
+// GobCodec is a Codec implementation based on encoding/gob.
+type GobCodec struct{}
+
+// Marshal implements the Codec interface.
+func (GobCodec) Marshal(value interface{}) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := gob.NewEncoder(buffer).Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal implements the Codec interface.
+func (GobCodec) Unmarshal(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// JSONCodec is a Codec implementation based on encoding/json.
+type JSONCodec struct{}
+
+// Marshal implements the Codec interface.
+func (JSONCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal implements the Codec interface.
+func (JSONCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// MsgPackCodec is a Codec implementation based on MessagePack.
+type MsgPackCodec struct{}
+
+// Marshal implements the Codec interface.
+func (MsgPackCodec) Marshal(value interface{}) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+// Unmarshal implements the Codec interface.
+func (MsgPackCodec) Unmarshal(data []byte, value interface{}) error {
+	return msgpack.Unmarshal(data, value)
+}
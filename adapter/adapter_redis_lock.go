@@ -0,0 +1,106 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/util/gconv"
+	"github.com/gogf/gf/util/grand"
+)
+
+const (
+	// defaultLockTTL is how long a distributed lock is held before it expires on
+	// its own, in case the holder crashes before releasing it.
+	defaultLockTTL = 5 * time.Second
+	// defaultLockMaxWait is how long a loser waits for the lock holder to finish
+	// before giving up and returning without a value.
+	defaultLockMaxWait = 3 * time.Second
+	// defaultLockPollInterval is how often a loser checks whether the key has
+	// appeared while waiting for the lock holder to finish.
+	defaultLockPollInterval = 50 * time.Millisecond
+)
+
+// unlockScript deletes the lock key only if it still holds the token this process
+// set it to, so that a lock is never released after its TTL has already expired and
+// been reacquired by someone else.
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// TieredLockOptions configures the distributed lock used by SetIfNotExistFuncLock
+// and GetOrSetFuncLock to ensure that, under a cache-miss stampede across many
+// processes, the loader function runs only once.
+type TieredLockOptions struct {
+	// LockTTL is how long the lock is held before it expires on its own.
+	// Defaults to defaultLockTTL.
+	LockTTL time.Duration
+	// MaxWait is how long a loser waits for the winner to populate the key before
+	// giving up. Defaults to defaultLockMaxWait.
+	MaxWait time.Duration
+	// PollInterval is how often a loser checks for the key while waiting.
+	// Defaults to defaultLockPollInterval.
+	PollInterval time.Duration
+}
+
+// withDefaults returns a copy of <o> with zero-valued fields replaced by their
+// package defaults.
+func (o TieredLockOptions) withDefaults() TieredLockOptions {
+	if o.LockTTL <= 0 {
+		o.LockTTL = defaultLockTTL
+	}
+	if o.MaxWait <= 0 {
+		o.MaxWait = defaultLockMaxWait
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultLockPollInterval
+	}
+	return o
+}
+
+// lockKey returns the Redis key used to hold the distributed lock for <key>.
+func (c *Redis) lockKey(key interface{}) string {
+	return c.options.Prefix + "lock:" + gconv.String(key)
+}
+
+// acquireLock attempts to acquire the distributed lock <lockKey> for <ttl>, using
+// `SET lockKey token NX PX ttl`. It returns the random token used to later release
+// the lock, and whether it was acquired.
+func (c *Redis) acquireLock(ctx context.Context, lockKey string, ttl time.Duration) (token string, ok bool, err error) {
+	token = grand.S(16)
+	v, err := c.client.DoVar(ctx, "SET", lockKey, token, "NX", "PX", uint64(ttl.Milliseconds()))
+	if err != nil {
+		return "", false, err
+	}
+	return token, !v.IsNil(), nil
+}
+
+// releaseLock releases <lockKey> using a compare-and-delete Lua script, so that it
+// never deletes a lock that another process has since acquired after this one's
+// TTL expired.
+func (c *Redis) releaseLock(ctx context.Context, lockKey string, token string) {
+	_, _ = c.client.DoVar(ctx, "EVAL", unlockScript, 1, lockKey, token)
+}
+
+// waitForKey polls <key> until it appears in the cache or lockOptions.MaxWait elapses.
+func (c *Redis) waitForKey(ctx context.Context, key interface{}, lockOptions TieredLockOptions) error {
+	deadline := time.Now().Add(lockOptions.MaxWait)
+	for time.Now().Before(deadline) {
+		v, err := c.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockOptions.PollInterval):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+// Options holds extra configuration for the Redis adapter.
+type Options struct {
+	// Prefix is prepended to every key written or read through the adapter,
+	// allowing multiple gcache instances to share the same Redis database
+	// without their keys colliding. It also scopes Keys, Values, Data, Size
+	// and Clear so that they only ever see keys belonging to this instance.
+	Prefix string
+
+	// Codec marshals values into bytes before they are written to Redis, so
+	// structs, slices and maps round-trip without losing type information. It is
+	// nil by default, in which case values are passed directly to the underlying
+	// gredis client exactly as before.
+	//
+	// Get, GetOrSet*, Values, Data and GetMulti are part of the gcache.Adapter
+	// interface and carry no destination type to decode into, so with a Codec
+	// configured they return the codec-encoded bytes as-is. Use GetInto to decode
+	// a key's value directly into a caller-supplied pointer.
+	Codec Codec
+
+	// LockOptions configures the distributed lock used by SetIfNotExistFuncLock and
+	// GetOrSetFuncLock to guard against cache-miss stampedes. Zero-valued fields
+	// fall back to sensible defaults, see TieredLockOptions.
+	LockOptions TieredLockOptions
+}
+
+// scanCount is the COUNT hint passed to SCAN while iterating the keyspace.
+// It only affects how many keys Redis examines per round trip, not how
+// many are returned in total.
+const scanCount = 100
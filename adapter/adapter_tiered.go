@@ -0,0 +1,370 @@
+// Copyright 2020 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gogf/gf/container/gvar"
+	"github.com/gogf/gf/os/gcache"
+	"github.com/gogf/gf/util/gconv"
+	"github.com/gogf/gf/util/grand"
+)
+
+// defaultTieredChannel is the Redis pub/sub channel used for local-cache
+// invalidation when TieredOptions.Channel is not set.
+const defaultTieredChannel = "gcache-adapter:tiered:invalidate"
+
+// TieredOptions holds extra configuration for the Tiered adapter.
+type TieredOptions struct {
+	// Channel is the Redis pub/sub channel used to broadcast invalidation
+	// messages between instances. Defaults to defaultTieredChannel.
+	Channel string
+
+	// InstanceID uniquely identifies this process among the instances sharing
+	// the same Channel, so that an instance can ignore the invalidation
+	// messages it published itself. Defaults to a random string.
+	InstanceID string
+}
+
+// invalidation is the message published on Channel whenever the local tier of
+// one instance needs to evict keys from the local tier of every other instance.
+type invalidation struct {
+	InstanceID string   `json:"instanceId"`
+	Keys       []string `json:"keys,omitempty"`
+	All        bool     `json:"all,omitempty"`
+}
+
+// Tiered is a gcache adapter implementing a two-tier cache: reads are served from
+// an in-process local adapter first, falling back to a shared Redis adapter on miss,
+// while writes go to both. Local tiers across instances are kept coherent by
+// publishing invalidation messages over a Redis pub/sub channel.
+type Tiered struct {
+	local   gcache.Adapter
+	remote  *Redis
+	bus     EventBus
+	options TieredOptions
+	cancel  context.CancelFunc
+}
+
+// NewTiered creates and returns a new two-tier gcache adapter, serving reads from
+// <local> first and falling back to <remote> on miss, while keeping every instance's
+// <local> tier coherent via a Redis pub/sub channel.
+func NewTiered(local gcache.Adapter, remote *Redis, options TieredOptions) gcache.Adapter {
+	if options.Channel == "" {
+		options.Channel = defaultTieredChannel
+	}
+	if options.InstanceID == "" {
+		options.InstanceID = grand.S(16)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Tiered{
+		local:   local,
+		remote:  remote,
+		bus:     NewRedisEventBus(remote),
+		options: options,
+		cancel:  cancel,
+	}
+	go t.listen(ctx)
+	return t
+}
+
+// listen runs until <ctx> is cancelled, evicting local keys whenever another
+// instance publishes an invalidation message. It ignores messages tagged with this
+// instance's own ID, since this instance has already updated its local tier directly.
+func (t *Tiered) listen(ctx context.Context) {
+	_ = t.bus.Subscribe(ctx, t.options.Channel, func(message string) {
+		var msg invalidation
+		if err := json.Unmarshal([]byte(message), &msg); err != nil {
+			return
+		}
+		if msg.InstanceID == t.options.InstanceID {
+			return
+		}
+		localCtx := context.Background()
+		if msg.All {
+			_ = t.local.Clear(localCtx)
+			return
+		}
+		for _, key := range msg.Keys {
+			_ = t.local.Remove(localCtx, key)
+		}
+	})
+}
+
+// publish broadcasts an invalidation message for <keys> to every other instance.
+func (t *Tiered) publish(ctx context.Context, keys ...string) {
+	data, err := json.Marshal(invalidation{InstanceID: t.options.InstanceID, Keys: keys})
+	if err != nil {
+		return
+	}
+	_ = t.bus.Publish(ctx, t.options.Channel, string(data))
+}
+
+// publishAll broadcasts an invalidation message telling every other instance to
+// clear its local tier entirely.
+func (t *Tiered) publishAll(ctx context.Context) {
+	data, err := json.Marshal(invalidation{InstanceID: t.options.InstanceID, All: true})
+	if err != nil {
+		return
+	}
+	_ = t.bus.Publish(ctx, t.options.Channel, string(data))
+}
+
+// Set sets cache with <key>-<value> pair in both tiers, which is expired after
+// <duration>, and notifies other instances to drop their local copy of <key>.
+func (t *Tiered) Set(ctx context.Context, key interface{}, value interface{}, duration time.Duration) error {
+	if err := t.remote.Set(ctx, key, value, duration); err != nil {
+		return err
+	}
+	if err := t.local.Set(ctx, key, value, duration); err != nil {
+		return err
+	}
+	t.publish(ctx, gconv.String(key))
+	return nil
+}
+
+// Update updates the value of <key> in the remote tier, drops the local tier's copy of
+// <key> and notifies other instances to drop their local copy of <key>.
+func (t *Tiered) Update(ctx context.Context, key interface{}, value interface{}) (oldValue *gvar.Var, exist bool, err error) {
+	oldValue, exist, err = t.remote.Update(ctx, key, value)
+	if err != nil {
+		return
+	}
+	_, _ = t.local.Remove(ctx, key)
+	t.publish(ctx, gconv.String(key))
+	return
+}
+
+// UpdateExpire updates the expiration of <key> in the remote tier and notifies other
+// instances to drop their local copy of <key>, since the local tier has no notion of
+// the new expiration.
+func (t *Tiered) UpdateExpire(ctx context.Context, key interface{}, duration time.Duration) (oldDuration time.Duration, err error) {
+	oldDuration, err = t.remote.UpdateExpire(ctx, key, duration)
+	if err != nil {
+		return
+	}
+	_, _ = t.local.Remove(ctx, key)
+	t.publish(ctx, gconv.String(key))
+	return
+}
+
+// GetExpire retrieves and returns the expiration of <key> from the remote tier, which
+// is always the source of truth for expiration.
+func (t *Tiered) GetExpire(ctx context.Context, key interface{}) (time.Duration, error) {
+	return t.remote.GetExpire(ctx, key)
+}
+
+// SetIfNotExist sets cache with <key>-<value> pair in both tiers if <key> does not
+// exist in the remote tier, and notifies other instances to drop their local copy.
+func (t *Tiered) SetIfNotExist(ctx context.Context, key interface{}, value interface{}, duration time.Duration) (bool, error) {
+	ok, err := t.remote.SetIfNotExist(ctx, key, value, duration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	_ = t.local.Set(ctx, key, value, duration)
+	t.publish(ctx, gconv.String(key))
+	return true, nil
+}
+
+// SetIfNotExistFunc sets `key` with the result of `f` in both tiers if `key` does not
+// exist in the remote tier, and notifies other instances to drop their local copy.
+func (t *Tiered) SetIfNotExistFunc(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (bool, error) {
+	var value interface{}
+	ok, err := t.remote.SetIfNotExistFunc(ctx, key, func() (interface{}, error) {
+		v, err := f()
+		value = v
+		return v, err
+	}, duration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	_ = t.local.Set(ctx, key, value, duration)
+	t.publish(ctx, gconv.String(key))
+	return true, nil
+}
+
+// SetIfNotExistFuncLock behaves like SetIfNotExistFunc, except that the remote tier is
+// written through Redis.SetIfNotExistFuncLock, so `f` runs only once across callers
+// racing on the same `key`.
+func (t *Tiered) SetIfNotExistFuncLock(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (bool, error) {
+	var value interface{}
+	ok, err := t.remote.SetIfNotExistFuncLock(ctx, key, func() (interface{}, error) {
+		v, err := f()
+		value = v
+		return v, err
+	}, duration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	_ = t.local.Set(ctx, key, value, duration)
+	t.publish(ctx, gconv.String(key))
+	return true, nil
+}
+
+// Sets batch sets cache with key-value pairs by <data> in both tiers, and notifies
+// other instances to drop their local copies of the affected keys.
+func (t *Tiered) Sets(ctx context.Context, data map[interface{}]interface{}, duration time.Duration) error {
+	if err := t.remote.Sets(ctx, data, duration); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(data))
+	for k, v := range data {
+		_ = t.local.Set(ctx, k, v, duration)
+		keys = append(keys, gconv.String(k))
+	}
+	t.publish(ctx, keys...)
+	return nil
+}
+
+// Get retrieves and returns the associated value of given <key>, serving it from the
+// local tier when present and falling back to the remote tier on miss.
+func (t *Tiered) Get(ctx context.Context, key interface{}) (*gvar.Var, error) {
+	v, err := t.local.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		return v, nil
+	}
+	v, err = t.remote.Get(ctx, key)
+	if err != nil || v == nil {
+		return v, err
+	}
+	t.warm(ctx, key, v.Val())
+	return v, nil
+}
+
+// warm populates the local tier with a value just read from the remote tier,
+// preserving its remaining TTL.
+func (t *Tiered) warm(ctx context.Context, key interface{}, value interface{}) {
+	duration, err := t.remote.GetExpire(ctx, key)
+	if err != nil {
+		return
+	}
+	if duration == -1 {
+		return
+	}
+	_ = t.local.Set(ctx, key, value, duration)
+}
+
+// GetOrSet retrieves and returns the value of <key>, or sets <key>-<value> pair in
+// both tiers and returns <value> if <key> does not exist in the cache.
+func (t *Tiered) GetOrSet(ctx context.Context, key interface{}, value interface{}, duration time.Duration) (*gvar.Var, error) {
+	v, err := t.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return gvar.New(value), t.Set(ctx, key, value, duration)
+	}
+	return v, nil
+}
+
+// GetOrSetFunc retrieves and returns the value of <key>, or sets <key> with the
+// result of <f> in both tiers if <key> does not exist in the cache.
+func (t *Tiered) GetOrSetFunc(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (*gvar.Var, error) {
+	v, err := t.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		return v, nil
+	}
+	value, err := f()
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return gvar.New(value), t.Set(ctx, key, value, duration)
+}
+
+// GetOrSetFuncLock behaves exactly like GetOrSetFunc. Unlike Redis.GetOrSetFuncLock it
+// takes no distributed lock, so `f` runs once for every caller that misses `key`
+// concurrently.
+func (t *Tiered) GetOrSetFuncLock(ctx context.Context, key interface{}, f func() (interface{}, error), duration time.Duration) (*gvar.Var, error) {
+	return t.GetOrSetFunc(ctx, key, f, duration)
+}
+
+// Contains returns true if <key> exists in the cache, checking the local tier first.
+func (t *Tiered) Contains(ctx context.Context, key interface{}) (bool, error) {
+	ok, err := t.local.Contains(ctx, key)
+	if err != nil || ok {
+		return ok, err
+	}
+	return t.remote.Contains(ctx, key)
+}
+
+// Remove deletes the one or more keys from both tiers, and notifies other instances
+// to drop their local copies of the affected keys.
+func (t *Tiered) Remove(ctx context.Context, keys ...interface{}) (value *gvar.Var, err error) {
+	value, err = t.remote.Remove(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.local.Remove(ctx, keys...); err != nil {
+		return value, err
+	}
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = gconv.String(key)
+	}
+	t.publish(ctx, names...)
+	return value, nil
+}
+
+// Data returns a copy of all key-value pairs in the cache, sourced from the remote
+// tier since the local tier may only hold a subset of the keyspace.
+func (t *Tiered) Data(ctx context.Context) (map[interface{}]interface{}, error) {
+	return t.remote.Data(ctx)
+}
+
+// Keys returns all keys in the cache, sourced from the remote tier since the local
+// tier may only hold a subset of the keyspace.
+func (t *Tiered) Keys(ctx context.Context) ([]interface{}, error) {
+	return t.remote.Keys(ctx)
+}
+
+// Values returns all values in the cache, sourced from the remote tier since the
+// local tier may only hold a subset of the keyspace.
+func (t *Tiered) Values(ctx context.Context) ([]interface{}, error) {
+	return t.remote.Values(ctx)
+}
+
+// Size returns the size of the cache, sourced from the remote tier since the local
+// tier may only hold a subset of the keyspace.
+func (t *Tiered) Size(ctx context.Context) (int, error) {
+	return t.remote.Size(ctx)
+}
+
+// Clear clears all data of both tiers, and notifies other instances to clear their
+// local tier too.
+// Note that this function is sensitive and should be carefully used.
+func (t *Tiered) Clear(ctx context.Context) error {
+	if err := t.remote.Clear(ctx); err != nil {
+		return err
+	}
+	if err := t.local.Clear(ctx); err != nil {
+		return err
+	}
+	t.publishAll(ctx)
+	return nil
+}
+
+// Close closes both tiers of the cache and stops listening for invalidation
+// messages, releasing the subscriber connection opened by NewTiered.
+func (t *Tiered) Close(ctx context.Context) error {
+	t.cancel()
+	if err := t.local.Close(ctx); err != nil {
+		return err
+	}
+	return t.remote.Close(ctx)
+}